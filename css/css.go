@@ -0,0 +1,211 @@
+// Package css provides sanitization handlers for the CSS declaration values
+// found inside an HTML style="" attribute. Each handler validates a single
+// property's value (a color, a length, a keyword, ...) in isolation; it is
+// the caller's job to split a style attribute into declarations and decide,
+// per element, which properties and handlers apply.
+package css
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Handler reports whether value is an acceptable value for the CSS property
+// it is registered against.
+type Handler func(value string) bool
+
+var (
+	reHexColor      = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+	reFuncColor     = regexp.MustCompile(`(?i)^(?:rgb|rgba|hsl|hsla)\(\s*[0-9.%,\s/]+\)$`)
+	reLength        = regexp.MustCompile(`(?i)^-?[0-9]*\.?[0-9]+(?:px|em|rem|ex|ch|vw|vh|vmin|vmax|cm|mm|in|pt|pc|%)$`)
+	reAngle         = regexp.MustCompile(`(?i)^-?[0-9]*\.?[0-9]+(?:deg|grad|rad|turn)$`)
+	reURL           = regexp.MustCompile(`(?i)^url\(\s*(['"]?)([^'"()]+)['"]?\s*\)$`)
+	reFloat         = regexp.MustCompile(`^-?[0-9]*\.?[0-9]+$`)
+	reTransformFunc = regexp.MustCompile(`(?i)^(?:translate(?:3d|x|y|z)?|rotate(?:3d|x|y|z)?|scale(?:3d|x|y|z)?|skew[xy]?|matrix(?:3d)?|perspective)\([^()]*\)$`)
+)
+
+// namedColors covers the CSS1 colour keywords plus "transparent",
+// "currentcolor", "grey", "orange", "pink" and "brown"; it is intentionally not the full CSS3 extended colour
+// keyword list, which is rarely needed for user-generated content.
+var namedColors = map[string]bool{
+	"transparent": true, "currentcolor": true, "black": true, "white": true,
+	"red": true, "green": true, "blue": true, "gray": true, "grey": true,
+	"silver": true, "maroon": true, "purple": true, "fuchsia": true,
+	"lime": true, "olive": true, "yellow": true, "navy": true, "teal": true,
+	"aqua": true, "orange": true, "pink": true, "brown": true,
+}
+
+// Color reports whether value is a hex color (#fff, #ffffff), an
+// rgb()/rgba()/hsl()/hsla() function, or one of the basic named colors.
+func Color(value string) bool {
+	v := strings.TrimSpace(value)
+
+	if reHexColor.MatchString(v) {
+		return true
+	}
+
+	if reFuncColor.MatchString(v) {
+		return true
+	}
+
+	return namedColors[strings.ToLower(v)]
+}
+
+// Length reports whether value is a CSS length: a number followed by a
+// recognised unit, or the unitless "0".
+func Length(value string) bool {
+	v := strings.TrimSpace(value)
+
+	if v == "0" {
+		return true
+	}
+
+	return reLength.MatchString(v)
+}
+
+// Angle reports whether value is a CSS angle (deg, grad, rad or turn).
+func Angle(value string) bool {
+	return reAngle.MatchString(strings.TrimSpace(value))
+}
+
+// Integer reports whether value parses cleanly as a base-10 integer.
+func Integer(value string) bool {
+	_, err := strconv.Atoi(strings.TrimSpace(value))
+
+	return err == nil
+}
+
+// Float returns a Handler that accepts a decimal number within [min, max],
+// e.g. Float(0, 1) for an opacity value.
+func Float(min, max float64) Handler {
+	return func(value string) bool {
+		v := strings.TrimSpace(value)
+		if !reFloat.MatchString(v) {
+			return false
+		}
+
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return false
+		}
+
+		return f >= min && f <= max
+	}
+}
+
+// Transform reports whether value is "none" or a space-separated list of
+// CSS transform functions (translate(), rotate(), scale(), skew(),
+// matrix(), perspective(), and their axis/3d variants).
+func Transform(value string) bool {
+	v := strings.TrimSpace(value)
+	if v == "" {
+		return false
+	}
+	if strings.EqualFold(v, "none") {
+		return true
+	}
+
+	fns := strings.Fields(v)
+	if len(fns) == 0 {
+		return false
+	}
+
+	for _, fn := range fns {
+		if !reTransformFunc.MatchString(fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Keyword returns a Handler that accepts only the given, case-insensitive
+// set of values, e.g. Keyword("left", "right", "center", "justify").
+func Keyword(allowed ...string) Handler {
+	set := make(map[string]bool, len(allowed))
+	for _, k := range allowed {
+		set[strings.ToLower(k)] = true
+	}
+
+	return func(value string) bool {
+		return set[strings.ToLower(strings.TrimSpace(value))]
+	}
+}
+
+// URL reports whether value is a CSS url(...) reference and, if so, returns
+// the unquoted URL it points at so the caller can apply its own scheme or
+// domain checks; CSS urls are where an unchecked style attribute can smuggle
+// a javascript: or disallowed-scheme reference past the rest of the
+// sanitizer.
+func URL(value string) (string, bool) {
+	m := reURL.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return "", false
+	}
+
+	return m[2], true
+}
+
+// ExtractURLs returns the unquoted URL of every url(...) reference found
+// anywhere in value, e.g. each of the comma-separated layers of a
+// multi-value "background-image". Unlike URL, it does not require value to
+// be a single bare url() — it's what callers should use to guard any
+// property whose grammar might embed a url() inside a larger value
+// (shorthand properties, image-set(), multiple backgrounds, ...).
+//
+// Parens are matched by depth rather than with a regexp, because an
+// unquoted url() argument is allowed to itself contain balanced parens
+// (url(javascript:alert(1)) being the adversarial case): a regexp that
+// stops at the first ")" would silently truncate the captured URL and let
+// the rest of it dodge the scheme check.
+func ExtractURLs(value string) []string {
+
+	var urls []string
+	lower := strings.ToLower(value)
+
+	for i := 0; i < len(value); {
+		rel := strings.Index(lower[i:], "url(")
+		if rel == -1 {
+			break
+		}
+
+		start := i + rel + len("url(")
+		depth := 1
+		j := start
+		for ; j < len(value) && depth > 0; j++ {
+			switch value[j] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+		}
+
+		if depth != 0 {
+			// Unterminated url(...); nothing more to safely extract.
+			break
+		}
+
+		raw := strings.TrimSpace(value[start : j-1])
+		raw = strings.Trim(raw, `'"`)
+		urls = append(urls, raw)
+
+		i = j
+	}
+
+	return urls
+}
+
+// SanitizedStyleValues runs value through handler and reports the value to
+// keep and whether it passed. bluemonday does not rewrite CSS values, only
+// accepts or rejects them, so the returned value is always value with its
+// surrounding whitespace trimmed (or "" when rejected); this mirrors the accept/reject contract of bluemonday.SanitizedURL for the
+// style attribute pipeline.
+func SanitizedStyleValues(handler Handler, value string) (string, bool) {
+	if handler == nil || !handler(value) {
+		return "", false
+	}
+
+	return strings.TrimSpace(value), true
+}
@@ -0,0 +1,149 @@
+package css
+
+import "testing"
+
+func TestColor(t *testing.T) {
+	good := []string{"#fff", "#ffFFff", "rgb(0,0,0)", "rgba(0, 0, 0, 0.5)", "hsl(120, 100%, 50%)", "red", "TRANSPARENT"}
+	bad := []string{"", "expression(alert(1))", "url(javascript:alert(1))", "#ffg", "javascript:alert(1)"}
+
+	for _, v := range good {
+		if !Color(v) {
+			t.Errorf("Color(%q) = false, want true", v)
+		}
+	}
+	for _, v := range bad {
+		if Color(v) {
+			t.Errorf("Color(%q) = true, want false", v)
+		}
+	}
+}
+
+func TestLength(t *testing.T) {
+	good := []string{"0", "10px", "1.5em", "100%", "-3pt"}
+	bad := []string{"", "10", "calc(100% - 10px)", "10xy"}
+
+	for _, v := range good {
+		if !Length(v) {
+			t.Errorf("Length(%q) = false, want true", v)
+		}
+	}
+	for _, v := range bad {
+		if Length(v) {
+			t.Errorf("Length(%q) = true, want false", v)
+		}
+	}
+}
+
+func TestAngle(t *testing.T) {
+	good := []string{"45deg", "-90deg", "1.5rad", "0.5turn"}
+	bad := []string{"", "45", "45px"}
+
+	for _, v := range good {
+		if !Angle(v) {
+			t.Errorf("Angle(%q) = false, want true", v)
+		}
+	}
+	for _, v := range bad {
+		if Angle(v) {
+			t.Errorf("Angle(%q) = true, want false", v)
+		}
+	}
+}
+
+func TestInteger(t *testing.T) {
+	if !Integer("42") || !Integer("-7") {
+		t.Error("Integer rejected a valid integer")
+	}
+	if Integer("4.2") || Integer("") || Integer("abc") {
+		t.Error("Integer accepted a non-integer")
+	}
+}
+
+func TestFloat(t *testing.T) {
+	f := Float(0, 1)
+
+	good := []string{"0", "1", "0.5", "0.99"}
+	bad := []string{"-0.1", "1.1", "", "abc"}
+
+	for _, v := range good {
+		if !f(v) {
+			t.Errorf("Float(0,1)(%q) = false, want true", v)
+		}
+	}
+	for _, v := range bad {
+		if f(v) {
+			t.Errorf("Float(0,1)(%q) = true, want false", v)
+		}
+	}
+}
+
+func TestTransform(t *testing.T) {
+	good := []string{"none", "rotate(45deg)", "translateX(10px) scale(1.2)", "matrix(1,0,0,1,0,0)"}
+	bad := []string{"", "expression(alert(1))", "rotate(45deg); background: url(x)"}
+
+	for _, v := range good {
+		if !Transform(v) {
+			t.Errorf("Transform(%q) = false, want true", v)
+		}
+	}
+	for _, v := range bad {
+		if Transform(v) {
+			t.Errorf("Transform(%q) = true, want false", v)
+		}
+	}
+}
+
+func TestKeyword(t *testing.T) {
+	k := Keyword("left", "right", "center")
+
+	if !k("Left") || !k("center") {
+		t.Error("Keyword rejected an allowed value")
+	}
+	if k("top") || k("") {
+		t.Error("Keyword accepted a disallowed value")
+	}
+}
+
+func TestURL(t *testing.T) {
+	u, ok := URL(`url(http://example.com/x.png)`)
+	if !ok || u != "http://example.com/x.png" {
+		t.Errorf("URL() = %q, %v, want http://example.com/x.png, true", u, ok)
+	}
+
+	if _, ok := URL("not-a-url"); ok {
+		t.Error("URL accepted a non-url() value")
+	}
+}
+
+func TestExtractURLs(t *testing.T) {
+	got := ExtractURLs(`url(good.png), url(javascript:alert(1))`)
+	want := []string{"good.png", "javascript:alert(1)"}
+
+	if len(got) != len(want) {
+		t.Fatalf("ExtractURLs() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ExtractURLs()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if ExtractURLs("red") != nil {
+		t.Error("ExtractURLs found a url() reference in a value with none")
+	}
+}
+
+func TestSanitizedStyleValues(t *testing.T) {
+	if _, ok := SanitizedStyleValues(Color, "javascript:alert(1)"); ok {
+		t.Error("SanitizedStyleValues accepted a value its handler should reject")
+	}
+
+	v, ok := SanitizedStyleValues(Color, " red ")
+	if !ok || v != "red" {
+		t.Errorf("SanitizedStyleValues() = %q, %v, want red, true", v, ok)
+	}
+
+	if _, ok := SanitizedStyleValues(nil, "red"); ok {
+		t.Error("SanitizedStyleValues accepted a value with a nil handler")
+	}
+}
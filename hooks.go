@@ -0,0 +1,137 @@
+package bluemonday
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Action tells the sanitizer what to do with an element or attribute after
+// a callback registered via OnElement or OnAttribute has inspected it.
+type Action int
+
+const (
+	// Keep leaves the element/attribute in place. If the callback mutated
+	// its context (ElementContext.Attrs, or AttrContext.Value) before
+	// returning Keep, the mutation still takes effect — Keep and Replace
+	// are treated identically; only Drop is distinct.
+	Keep Action = iota
+
+	// Drop removes the attribute, or the element's start and end tags; a
+	// dropped element's children and text are still sanitized and kept.
+	Drop
+
+	// Replace is a synonym for Keep after mutating the context; the two
+	// are handled the same way.
+	Replace
+)
+
+// ElementContext is passed to an OnElement callback. Name is the lowercased
+// element name; Attrs is the attribute list that survived the built-in
+// allow/deny checks. A callback that wants to change attributes (add
+// loading="lazy", drop one, ...) mutates Attrs in place.
+type ElementContext struct {
+	Name  string
+	Attrs []html.Attribute
+}
+
+// AttrContext is passed to an OnAttribute callback for a single attribute
+// that survived the built-in allow/deny checks for its element. A callback
+// that wants to rewrite the value (e.g. upgrading href from http to https)
+// mutates Value in place.
+type AttrContext struct {
+	Element string
+	Key     string
+	Value   string
+}
+
+type elementHook func(*ElementContext) Action
+type attributeHook func(*AttrContext) Action
+
+// OnElement registers fn to run on every occurrence of name after the
+// built-in allow/deny checks and before serialization. It covers policies
+// that would otherwise require a second x/net/html pass over the sanitized
+// output, e.g. adding loading="lazy" and decoding="async" to <img>, or
+// forcing an href to https. Multiple hooks registered for the same element
+// run in registration order; any of them returning Drop removes the
+// element's tags, leaving its children and text in place. fn only ever sees a single element's own tag and attributes —
+// it has no visibility into that element's children, so it cannot decide
+// whether the element "is empty" in the rendered-content sense.
+func (p *policy) OnElement(name string, fn func(*ElementContext) Action) *policy {
+
+	if p.elementHooks == nil {
+		p.elementHooks = make(map[string][]elementHook)
+	}
+
+	name = strings.ToLower(name)
+	p.elementHooks[name] = append(p.elementHooks[name], fn)
+
+	return p
+}
+
+// OnAttribute registers fn to run on every occurrence of the named
+// attribute, on any element, after the built-in allow/deny checks and
+// before serialization. Concrete use cases include rewriting relative
+// image URLs to a CDN or upgrading an href from http to https.
+func (p *policy) OnAttribute(name string, fn func(*AttrContext) Action) *policy {
+
+	if p.attributeHooks == nil {
+		p.attributeHooks = make(map[string][]attributeHook)
+	}
+
+	name = strings.ToLower(name)
+	p.attributeHooks[name] = append(p.attributeHooks[name], fn)
+
+	return p
+}
+
+// runAttributeHooks applies any hooks registered for attrs' keys, dropping
+// or replacing values as directed, and returns the attribute list to keep.
+func (p *policy) runAttributeHooks(el string, attrs []html.Attribute) []html.Attribute {
+
+	if len(p.attributeHooks) == 0 {
+		return attrs
+	}
+
+	var out []html.Attribute
+
+	for _, a := range attrs {
+		ctx := &AttrContext{Element: el, Key: a.Key, Value: a.Val}
+		dropped := false
+
+		for _, fn := range p.attributeHooks[strings.ToLower(a.Key)] {
+			if fn(ctx) == Drop {
+				dropped = true
+				break
+			}
+			a.Val = ctx.Value
+		}
+
+		if !dropped {
+			out = append(out, a)
+		}
+	}
+
+	return out
+}
+
+// runElementHooks applies any hooks registered for el, dropping the element
+// or keeping it with whatever attributes the hooks left in ctx.Attrs. ok
+// reports whether the element should still be serialized.
+func (p *policy) runElementHooks(el string, attrs []html.Attribute) (kept []html.Attribute, ok bool) {
+
+	hooks := p.elementHooks[el]
+	if len(hooks) == 0 {
+		return attrs, true
+	}
+
+	ctx := &ElementContext{Name: el, Attrs: attrs}
+
+	for _, fn := range hooks {
+		if fn(ctx) == Drop {
+			return nil, false
+		}
+	}
+
+	return ctx.Attrs, true
+}
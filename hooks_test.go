@@ -0,0 +1,110 @@
+package bluemonday
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOnElementDropRemovesTagsKeepsContent(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("p", "b")
+	p.OnElement("b", func(ctx *ElementContext) Action {
+		return Drop
+	})
+
+	out := p.Sanitize(`<p>hi <b>bold</b></p>`)
+	if strings.Contains(out, "<b>") {
+		t.Errorf("Sanitize() = %q, <b> should have been dropped by the hook", out)
+	}
+	if !strings.Contains(out, "hi") {
+		t.Errorf("Sanitize() = %q, surrounding content should survive", out)
+	}
+	if strings.Contains(out, "</b>") {
+		t.Errorf("Sanitize() = %q, the matching end tag should be suppressed along with the dropped start tag", out)
+	}
+	if out != "<p>hi bold</p>" {
+		t.Errorf("Sanitize() = %q, want <p>hi bold</p>", out)
+	}
+}
+
+func TestOnElementDropSuppressesNestedEndTags(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("p", "b", "i")
+	p.OnElement("b", func(ctx *ElementContext) Action {
+		return Drop
+	})
+
+	out := p.Sanitize(`<p>a <b>b <i>c</i> d</b> e</p>`)
+	if strings.Contains(out, "<b>") || strings.Contains(out, "</b>") {
+		t.Errorf("Sanitize() = %q, both the start and end <b> tags should be gone", out)
+	}
+	if !strings.Contains(out, "<i>c</i>") {
+		t.Errorf("Sanitize() = %q, the nested, allowed <i> should survive", out)
+	}
+}
+
+func TestOnElementKeepAppliesMutation(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("img")
+	p.AllowAttrs("src").OnElements("img")
+	p.AllowURLSchemes("http", "https")
+	p.RequireParseableURLs(true)
+	p.OnElement("img", func(ctx *ElementContext) Action {
+		for i, a := range ctx.Attrs {
+			if a.Key == "src" {
+				ctx.Attrs[i].Val = "https://cdn.example.com/x.png"
+			}
+		}
+		return Keep
+	})
+
+	out := p.Sanitize(`<img src="https://example.com/x.png">`)
+	if !strings.Contains(out, `src="https://cdn.example.com/x.png"`) {
+		t.Errorf("Sanitize() = %q, Keep should still apply the hook's mutation to Attrs", out)
+	}
+}
+
+func TestOnAttributeReplaceAndDrop(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("a")
+	p.AllowAttrs("href", "title").OnElements("a")
+	p.AllowURLSchemes("http", "https")
+	p.RequireParseableURLs(true)
+	p.OnAttribute("href", func(ctx *AttrContext) Action {
+		ctx.Value = strings.Replace(ctx.Value, "http://", "https://", 1)
+		return Replace
+	})
+	p.OnAttribute("title", func(ctx *AttrContext) Action {
+		return Drop
+	})
+
+	out := p.Sanitize(`<a href="http://example.com" title="secret">link</a>`)
+	if !strings.Contains(out, `href="https://example.com"`) {
+		t.Errorf("Sanitize() = %q, href should have been upgraded to https by the hook", out)
+	}
+	if strings.Contains(out, "title") {
+		t.Errorf("Sanitize() = %q, title should have been dropped by the hook", out)
+	}
+}
+
+func TestMultipleElementHooksRunInRegistrationOrder(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("p")
+	p.AllowAttrs("data-a", "data-b").OnElements("p")
+
+	var order []string
+	p.OnElement("p", func(ctx *ElementContext) Action {
+		order = append(order, "first")
+		return Keep
+	})
+	p.OnElement("p", func(ctx *ElementContext) Action {
+		order = append(order, "second")
+		return Keep
+	})
+
+	p.Sanitize(`<p>hi</p>`)
+
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Errorf("hook run order = %v, want [first second]", order)
+	}
+}
@@ -0,0 +1,142 @@
+package bluemonday
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// RequireNoReferrerOnLinks will result in all <a> tags having
+// rel="noreferrer" added to them if one does not already exist, merged into
+// any existing rel= token list rather than overwriting it.
+func (p *policy) RequireNoReferrerOnLinks(require bool) *policy {
+	p.requireNoReferrer = require
+
+	return p
+}
+
+// RequireNoOpenerOnLinks will result in all <a> tags having rel="noopener"
+// added to them if one does not already exist, merged into any existing
+// rel= token list rather than overwriting it.
+func (p *policy) RequireNoOpenerOnLinks(require bool) *policy {
+	p.requireNoOpener = require
+
+	return p
+}
+
+// AddTargetBlankToFullyQualifiedLinks adds target="_blank" to <a> tags
+// whose href is an absolute URL with a host that isn't registered via
+// LocalHosts. Combine with RequireNoOpenerOnLinks/RequireNoReferrerOnLinks,
+// since target="_blank" alone leaves the linked page able to reach back
+// through window.opener (reverse tabnabbing).
+func (p *policy) AddTargetBlankToFullyQualifiedLinks(require bool) *policy {
+	p.addTargetBlank = require
+
+	return p
+}
+
+// LocalHosts registers the hosts that AddTargetBlankToFullyQualifiedLinks
+// treats as "ours": links to them do not get target="_blank".
+func (p *policy) LocalHosts(hosts ...string) *policy {
+
+	if p.localHosts == nil {
+		p.localHosts = make(map[string]bool)
+	}
+
+	for _, h := range hosts {
+		p.localHosts[strings.ToLower(h)] = true
+	}
+
+	return p
+}
+
+// RequireCrossOriginAnonymousOnMedia adds crossorigin="anonymous" to
+// <img>, <audio>, <video> and <script> tags that don't already specify a
+// crossorigin value.
+func (p *policy) RequireCrossOriginAnonymousOnMedia(require bool) *policy {
+	p.requireCrossOriginAnonymous = require
+
+	return p
+}
+
+// crossOriginElements lists the elements RequireCrossOriginAnonymousOnMedia
+// applies to.
+var crossOriginElements = map[string]bool{
+	"img":    true,
+	"audio":  true,
+	"video":  true,
+	"script": true,
+}
+
+// applyLinkSafety adds the configured rel=, target= and crossorigin=
+// attributes for el, merging into any existing rel= token list rather than
+// overwriting it. It runs after sanitizeAttrs's allow/deny checks, so it
+// only ever adds to an attribute set that's already been approved.
+func (p *policy) applyLinkSafety(el string, attrs []html.Attribute) []html.Attribute {
+
+	if el == "a" {
+		if p.requireNoFollow {
+			attrs = addRelToken(attrs, "nofollow")
+		}
+		if p.requireNoReferrer {
+			attrs = addRelToken(attrs, "noreferrer")
+		}
+		if p.requireNoOpener {
+			attrs = addRelToken(attrs, "noopener")
+		}
+		if p.addTargetBlank {
+			attrs = p.addTargetBlankIfForeign(attrs)
+		}
+	}
+
+	if p.requireCrossOriginAnonymous && crossOriginElements[el] {
+		attrs = addCrossOriginIfMissing(attrs)
+	}
+
+	return attrs
+}
+
+// addTargetBlankIfForeign adds target="_blank" to attrs when its href is an
+// absolute URL whose host isn't registered via LocalHosts and no target=
+// attribute is already present.
+func (p *policy) addTargetBlankIfForeign(attrs []html.Attribute) []html.Attribute {
+
+	for _, a := range attrs {
+		if strings.ToLower(a.Key) != "href" {
+			continue
+		}
+
+		u, err := url.Parse(a.Val)
+		if err != nil || !u.IsAbs() {
+			return attrs
+		}
+
+		if p.localHosts[strings.ToLower(u.Hostname())] {
+			return attrs
+		}
+
+		for _, existing := range attrs {
+			if strings.ToLower(existing.Key) == "target" {
+				return attrs
+			}
+		}
+
+		return append(attrs, html.Attribute{Key: "target", Val: "_blank"})
+	}
+
+	return attrs
+}
+
+// addCrossOriginIfMissing appends crossorigin="anonymous" unless attrs
+// already specifies one.
+func addCrossOriginIfMissing(attrs []html.Attribute) []html.Attribute {
+
+	for _, a := range attrs {
+		if strings.ToLower(a.Key) == "crossorigin" {
+			return attrs
+		}
+	}
+
+	return append(attrs, html.Attribute{Key: "crossorigin", Val: "anonymous"})
+}
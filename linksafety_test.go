@@ -0,0 +1,79 @@
+package bluemonday
+
+import (
+	"strings"
+	"testing"
+)
+
+func linkSafetyPolicyForTest() *policy {
+	p := NewPolicy()
+	p.AllowElements("a", "img")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowAttrs("rel", "target").OnElements("a")
+	p.AllowAttrs("src", "crossorigin").OnElements("img")
+	p.AllowURLSchemes("http", "https")
+	p.RequireParseableURLs(true)
+	return p
+}
+
+func TestRequireNoReferrerAndNoOpenerMergeIntoRel(t *testing.T) {
+	p := linkSafetyPolicyForTest()
+	p.RequireNoReferrerOnLinks(true)
+	p.RequireNoOpenerOnLinks(true)
+
+	out := p.Sanitize(`<a href="https://example.com" rel="author">link</a>`)
+	if !strings.Contains(out, `rel="author noreferrer noopener"`) {
+		t.Errorf("Sanitize() = %q, want noreferrer and noopener merged into the existing rel list", out)
+	}
+}
+
+func TestRequireNoReferrerDoesNotDuplicateExistingToken(t *testing.T) {
+	p := linkSafetyPolicyForTest()
+	p.RequireNoReferrerOnLinks(true)
+
+	out := p.Sanitize(`<a href="https://example.com" rel="noreferrer">link</a>`)
+	if strings.Count(out, "noreferrer") != 1 {
+		t.Errorf("Sanitize() = %q, noreferrer should not be duplicated", out)
+	}
+}
+
+func TestAddTargetBlankSkipsLocalHosts(t *testing.T) {
+	p := linkSafetyPolicyForTest()
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	p.LocalHosts("example.com")
+
+	out := p.Sanitize(`<a href="https://example.com">local</a>`)
+	if strings.Contains(out, "target") {
+		t.Errorf("Sanitize() = %q, a local host should not get target=_blank", out)
+	}
+
+	out = p.Sanitize(`<a href="https://other.com">foreign</a>`)
+	if !strings.Contains(out, `target="_blank"`) {
+		t.Errorf("Sanitize() = %q, a foreign host should get target=_blank", out)
+	}
+}
+
+func TestAddTargetBlankDoesNotOverrideExistingTarget(t *testing.T) {
+	p := linkSafetyPolicyForTest()
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+
+	out := p.Sanitize(`<a href="https://other.com" target="_self">foreign</a>`)
+	if !strings.Contains(out, `target="_self"`) || strings.Contains(out, "_blank") {
+		t.Errorf("Sanitize() = %q, an explicit target should not be overwritten", out)
+	}
+}
+
+func TestRequireCrossOriginAnonymousOnMedia(t *testing.T) {
+	p := linkSafetyPolicyForTest()
+	p.RequireCrossOriginAnonymousOnMedia(true)
+
+	out := p.Sanitize(`<img src="https://example.com/x.png">`)
+	if !strings.Contains(out, `crossorigin="anonymous"`) {
+		t.Errorf("Sanitize() = %q, img should get crossorigin=anonymous", out)
+	}
+
+	out = p.Sanitize(`<img src="https://example.com/x.png" crossorigin="use-credentials">`)
+	if !strings.Contains(out, `crossorigin="use-credentials"`) {
+		t.Errorf("Sanitize() = %q, an explicit crossorigin should not be overwritten", out)
+	}
+}
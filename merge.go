@@ -0,0 +1,250 @@
+package bluemonday
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Clone returns a deep copy of p so callers can derive a new, independent
+// policy from an existing one without mutating the original — e.g. a strict
+// "description" policy built from a permissive "full renderer" policy.
+func (p *policy) Clone() *policy {
+
+	clone := NewPolicy()
+
+	clone.allowDocType = p.allowDocType
+	clone.requireNoFollow = p.requireNoFollow
+	clone.requireParseableURLs = p.requireParseableURLs
+	clone.allowRelativeURLs = p.allowRelativeURLs
+	clone.requireNoReferrer = p.requireNoReferrer
+	clone.requireNoOpener = p.requireNoOpener
+	clone.addTargetBlank = p.addTargetBlank
+	clone.requireCrossOriginAnonymous = p.requireCrossOriginAnonymous
+	clone.urls = p.urls.clone()
+
+	for host := range p.localHosts {
+		if clone.localHosts == nil {
+			clone.localHosts = make(map[string]bool)
+		}
+		clone.localHosts[host] = true
+	}
+
+	for scheme := range p.urlSchemes {
+		clone.urlSchemes[scheme] = true
+	}
+
+	for el, attrs := range p.elsAndAttrs {
+		cloned := make(map[string]attrPolicy, len(attrs))
+		for attr, ap := range attrs {
+			cloned[attr] = ap
+		}
+		clone.elsAndAttrs[el] = cloned
+	}
+
+	for attr, ap := range p.globalAttrs {
+		clone.globalAttrs[attr] = ap
+	}
+
+	for el := range p.elsWithoutAttrs {
+		clone.elsWithoutAttrs[el] = true
+	}
+
+	for el, props := range p.styles {
+		cloned := make(map[string]stylePolicy, len(props))
+		for prop, sp := range props {
+			cloned[prop] = sp
+		}
+		clone.styles[el] = cloned
+	}
+
+	for prop, sp := range p.globalStyles {
+		clone.globalStyles[prop] = sp
+	}
+
+	for name, hooks := range p.elementHooks {
+		if clone.elementHooks == nil {
+			clone.elementHooks = make(map[string][]elementHook)
+		}
+		clone.elementHooks[name] = append([]elementHook(nil), hooks...)
+	}
+
+	for name, hooks := range p.attributeHooks {
+		if clone.attributeHooks == nil {
+			clone.attributeHooks = make(map[string][]attributeHook)
+		}
+		clone.attributeHooks[name] = append([]attributeHook(nil), hooks...)
+	}
+
+	return clone
+}
+
+// MergeStrategy controls how Merge resolves an attribute or style regexp
+// that is set on both policies for the same element/attribute (or
+// element/property) pair.
+type MergeStrategy int
+
+const (
+	// MergeStrategyAlternation combines both regexps into a single pattern
+	// that accepts anything either one accepts. This is the strategy Merge
+	// uses.
+	MergeStrategyAlternation MergeStrategy = iota
+
+	// MergeStrategyLastWins keeps the incoming policy's regexp, discarding
+	// the receiver's.
+	MergeStrategyLastWins
+)
+
+// Merge combines other into p, mutating and returning p. It unions the
+// element/attribute whitelists, style whitelists, URL schemes and
+// elsWithoutAttrs, and takes the stricter of the two policies' boolean
+// flags: "require" flags (requireNoFollow, requireParseableURLs, ...) are
+// OR-ed, and "allow" flags (allowDocType, allowRelativeURLs) are AND-ed, so
+// on those flags the merged policy is at least as strict as either input.
+// Conflicting attribute/style regexps are combined as an
+// alternation; use MergeWithStrategy for last-wins instead.
+func (p *policy) Merge(other *policy) *policy {
+	return p.MergeWithStrategy(other, MergeStrategyAlternation)
+}
+
+// MergeWithStrategy is Merge with an explicit conflict resolution strategy
+// for attribute/style regexps that are set on both sides.
+func (p *policy) MergeWithStrategy(other *policy, strategy MergeStrategy) *policy {
+
+	p.requireNoFollow = p.requireNoFollow || other.requireNoFollow
+	p.requireParseableURLs = p.requireParseableURLs || other.requireParseableURLs
+	p.allowRelativeURLs = p.allowRelativeURLs && other.allowRelativeURLs
+	p.allowDocType = p.allowDocType && other.allowDocType
+	p.requireNoReferrer = p.requireNoReferrer || other.requireNoReferrer
+	p.requireNoOpener = p.requireNoOpener || other.requireNoOpener
+	p.addTargetBlank = p.addTargetBlank || other.addTargetBlank
+	p.requireCrossOriginAnonymous = p.requireCrossOriginAnonymous || other.requireCrossOriginAnonymous
+	p.urls = p.urls.mergeInto(other.urls)
+
+	for host := range other.localHosts {
+		if p.localHosts == nil {
+			p.localHosts = make(map[string]bool)
+		}
+		p.localHosts[host] = true
+	}
+
+	for scheme := range other.urlSchemes {
+		p.urlSchemes[scheme] = true
+	}
+
+	for el := range other.elsWithoutAttrs {
+		p.elsWithoutAttrs[el] = true
+	}
+
+	for attr, ap := range other.globalAttrs {
+		p.globalAttrs[attr] = mergeAttrPolicy(p.globalAttrs[attr], ap, strategy)
+	}
+
+	for el, attrs := range other.elsAndAttrs {
+		if _, ok := p.elsAndAttrs[el]; !ok {
+			p.elsAndAttrs[el] = make(map[string]attrPolicy)
+		}
+		for attr, ap := range attrs {
+			p.elsAndAttrs[el][attr] = mergeAttrPolicy(p.elsAndAttrs[el][attr], ap, strategy)
+		}
+	}
+
+	for prop, sp := range other.globalStyles {
+		p.globalStyles[prop] = mergeStylePolicy(p.globalStyles[prop], sp, strategy)
+	}
+
+	for el, props := range other.styles {
+		if _, ok := p.styles[el]; !ok {
+			p.styles[el] = make(map[string]stylePolicy)
+		}
+		for prop, sp := range props {
+			p.styles[el][prop] = mergeStylePolicy(p.styles[el][prop], sp, strategy)
+		}
+	}
+
+	for name, hooks := range other.elementHooks {
+		if p.elementHooks == nil {
+			p.elementHooks = make(map[string][]elementHook)
+		}
+		p.elementHooks[name] = append(p.elementHooks[name], hooks...)
+	}
+
+	for name, hooks := range other.attributeHooks {
+		if p.attributeHooks == nil {
+			p.attributeHooks = make(map[string][]attributeHook)
+		}
+		p.attributeHooks[name] = append(p.attributeHooks[name], hooks...)
+	}
+
+	return p
+}
+
+func mergeAttrPolicy(existing, incoming attrPolicy, strategy MergeStrategy) attrPolicy {
+	switch {
+	case existing.regexp == nil:
+		return incoming
+	case incoming.regexp == nil:
+		return existing
+	case strategy == MergeStrategyLastWins:
+		return incoming
+	default:
+		return attrPolicy{regexp: alternate(existing.regexp, incoming.regexp)}
+	}
+}
+
+func mergeStylePolicy(existing, incoming stylePolicy, strategy MergeStrategy) stylePolicy {
+	merged := stylePolicy{handler: existing.handler}
+	if incoming.handler != nil {
+		merged.handler = incoming.handler
+	}
+
+	switch {
+	case existing.regexp == nil:
+		merged.regexp = incoming.regexp
+	case incoming.regexp == nil:
+		merged.regexp = existing.regexp
+	case strategy == MergeStrategyLastWins:
+		merged.regexp = incoming.regexp
+	default:
+		merged.regexp = alternate(existing.regexp, incoming.regexp)
+	}
+
+	return merged
+}
+
+func alternate(a, b *regexp.Regexp) *regexp.Regexp {
+	return regexp.MustCompile("(?:" + a.String() + ")|(?:" + b.String() + ")")
+}
+
+// Policies is a named registry of policies, so applications that sanitize
+// several kinds of content — one policy per markup language, or a strict
+// policy for summaries alongside a permissive one for full bodies — can
+// look one up by name instead of threading *policy values through their own
+// plumbing.
+type Policies struct {
+	named map[string]*policy
+}
+
+// NewPolicies returns an empty Policies registry.
+func NewPolicies() *Policies {
+	return &Policies{named: make(map[string]*policy)}
+}
+
+// Register adds p to the registry under name, replacing any policy
+// previously registered under that name.
+func (ps *Policies) Register(name string, p *policy) {
+	ps.named[name] = p
+}
+
+// Sanitize looks up the policy registered under name and uses it to
+// sanitize s. It returns an error if name was never registered — a policy
+// name is often selected at runtime (per request, per tenant), so a
+// missing one shouldn't be able to crash a process handling untrusted
+// input.
+func (ps *Policies) Sanitize(name, s string) (string, error) {
+	p, ok := ps.named[name]
+	if !ok {
+		return "", fmt.Errorf("bluemonday: no policy registered under name %q", name)
+	}
+
+	return p.Sanitize(s), nil
+}
@@ -0,0 +1,116 @@
+package bluemonday
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestCloneIsIndependentAndPreservesDomainRestriction(t *testing.T) {
+	base := NewPolicy()
+	base.AllowElements("a")
+	base.AllowAttrs("href").OnElements("a")
+	base.AllowURLSchemes("http", "https")
+	base.RequireParseableURLs(true)
+	base.AllowURLDomains("trusted.org")
+
+	strict := base.Clone()
+	base.AllowURLDomains("evil.com")
+
+	out := strict.Sanitize(`<a href="http://evil.com">x</a>`)
+	if strings.Contains(out, `href="http://evil.com"`) {
+		t.Errorf("Sanitize() = %q, clone should still reject evil.com even though it was later allowed on base", out)
+	}
+}
+
+func TestCloneAndMergePreserveHooks(t *testing.T) {
+	base := NewPolicy()
+	base.AllowElements("a")
+	base.AllowAttrs("href").OnElements("a")
+	base.OnElement("a", func(ctx *ElementContext) Action {
+		ctx.Attrs = append(ctx.Attrs, html.Attribute{Key: "data-hooked", Val: "yes"})
+		return Keep
+	})
+
+	clone := base.Clone()
+	out := clone.Sanitize(`<a href="/x">link</a>`)
+	if !strings.Contains(out, `data-hooked="yes"`) {
+		t.Errorf("Sanitize() = %q, Clone() should have preserved the element hook", out)
+	}
+
+	merged := NewPolicy()
+	merged.AllowElements("a")
+	merged.AllowAttrs("href").OnElements("a")
+	merged.Merge(base)
+
+	out = merged.Sanitize(`<a href="/x">link</a>`)
+	if !strings.Contains(out, `data-hooked="yes"`) {
+		t.Errorf("Sanitize() = %q, Merge() should have pulled in the other policy's element hook", out)
+	}
+}
+
+func TestMergeBooleanFlagDirections(t *testing.T) {
+	strict := NewPolicy()
+	strict.RequireNoFollowOnLinks(false)
+	strict.AllowDocType(false)
+	strict.AllowRelativeURLs(false)
+
+	permissive := NewPolicy()
+	permissive.RequireNoFollowOnLinks(true)
+	permissive.AllowDocType(true)
+	permissive.AllowRelativeURLs(true)
+
+	merged := strict.Clone().Merge(permissive)
+
+	if !merged.requireNoFollow {
+		t.Error(`Merge() requireNoFollow = false, want true: a "require" flag should be OR-ed toward the stricter (safer) side`)
+	}
+	if merged.allowDocType {
+		t.Error(`Merge() allowDocType = true, want false: an "allow" flag should be AND-ed toward the stricter side`)
+	}
+	if merged.allowRelativeURLs {
+		t.Error(`Merge() allowRelativeURLs = true, want false: an "allow" flag should be AND-ed toward the stricter side`)
+	}
+}
+
+func TestMergeAllowRelativeURLsStaysStrict(t *testing.T) {
+	strict := NewPolicy()
+	strict.AllowElements("a")
+	strict.AllowAttrs("href").OnElements("a")
+	strict.AllowURLSchemes("http", "https")
+	strict.AllowRelativeURLs(false)
+
+	permissive := NewPolicy()
+	permissive.AllowElements("a")
+	permissive.AllowAttrs("href").OnElements("a")
+	permissive.AllowURLSchemes("http", "https")
+	permissive.AllowRelativeURLs(true)
+
+	merged := strict.Clone().Merge(permissive)
+
+	out := merged.Sanitize(`<a href="/local/path">x</a>`)
+	if strings.Contains(out, "href") {
+		t.Errorf("Sanitize() = %q, merging with a permissive policy should not start allowing relative URLs that strict forbade", out)
+	}
+}
+
+func TestPoliciesSanitizeReturnsErrorForUnregisteredName(t *testing.T) {
+	ps := NewPolicies()
+
+	if _, err := ps.Sanitize("missing", "<p>hi</p>"); err == nil {
+		t.Error("Sanitize() error = nil, want an error for an unregistered policy name")
+	}
+
+	p := NewPolicy()
+	p.AllowElements("p")
+	ps.Register("safe", p)
+
+	out, err := ps.Sanitize("safe", "<p>hi</p>")
+	if err != nil {
+		t.Fatalf("Sanitize() error = %v, want nil", err)
+	}
+	if out != "<p>hi</p>" {
+		t.Errorf("Sanitize() = %q, want <p>hi</p>", out)
+	}
+}
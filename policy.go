@@ -31,6 +31,43 @@ type policy struct {
 
 	// map[htmlElementName]bool
 	elsWithoutAttrs map[string]bool
+
+	// map[htmlElementName]map[cssPropertyName]stylePolicy
+	styles map[string]map[string]stylePolicy
+
+	// map[cssPropertyName]stylePolicy
+	globalStyles map[string]stylePolicy
+
+	// map[htmlElementName][]elementHook, run after the built-in allow/deny
+	// checks and before serialization
+	elementHooks map[string][]elementHook
+
+	// map[htmlAttributeName][]attributeHook, run after the built-in
+	// allow/deny checks and before serialization
+	attributeHooks map[string][]attributeHook
+
+	// scheme regexp, host allowlist and final rewrite hook applied to
+	// every href/src/cite URL once requireParseableURLs passes it; nil
+	// until one of AllowURLSchemesMatching/AllowURLDomains/
+	// RequireSanitizedURL is called
+	urls *urlPolicy
+
+	// When true, add rel="noreferrer" to HTML anchors
+	requireNoReferrer bool
+
+	// When true, add rel="noopener" to HTML anchors
+	requireNoOpener bool
+
+	// When true, add target="_blank" to HTML anchors whose href host is
+	// not in localHosts
+	addTargetBlank bool
+
+	// map[hostname]bool, the hosts AddTargetBlankToFullyQualifiedLinks
+	// treats as "ours"
+	localHosts map[string]bool
+
+	// When true, add crossorigin="anonymous" to img/audio/video/script
+	requireCrossOriginAnonymous bool
 }
 
 type attrPolicy struct {
@@ -56,6 +93,8 @@ func NewPolicy() *policy {
 	p.elsAndAttrs = make(map[string]map[string]attrPolicy)
 	p.globalAttrs = make(map[string]attrPolicy)
 	p.elsWithoutAttrs = make(map[string]bool)
+	p.styles = make(map[string]map[string]stylePolicy)
+	p.globalStyles = make(map[string]stylePolicy)
 
 	p.addDefaultElsWithoutAttrs()
 
@@ -67,11 +106,12 @@ func NewPolicy() *policy {
 // the whitelisted attribute.
 //
 // Examples:
-//   AllowAttrs("title").Globally()
-//   AllowAttrs("abbr").OnElements("td", "th")
-//   AllowAttrs("colspan", "rowspan").Matching(
-//           regexp.MustCompile("[0-9]+"),
-//       ).OnElements("td", "th")
+//
+//	AllowAttrs("title").Globally()
+//	AllowAttrs("abbr").OnElements("td", "th")
+//	AllowAttrs("colspan", "rowspan").Matching(
+//	        regexp.MustCompile("[0-9]+"),
+//	    ).OnElements("td", "th")
 //
 // The attribute policy is only added to the core policy when either Globally()
 // or OnElements(...) are called.
@@ -0,0 +1,304 @@
+package bluemonday
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// skipContentElements lists elements whose text content must be discarded
+// along with the tag itself when the element isn't on the allowlist, since
+// leaving their content behind (e.g. the body of a dropped <script>) would
+// usually be worse than dropping the tag alone.
+var skipContentElements = map[string]bool{
+	"script": true,
+	"style":  true,
+}
+
+// urlAttrs lists the attributes that carry a URL and are therefore subject
+// to requireParseableURLs/urlSchemes/allowRelativeURLs, per
+// RequireParseableURLs' doc comment.
+var urlAttrs = map[string]bool{
+	"href": true,
+	"src":  true,
+	"cite": true,
+}
+
+// Sanitize takes a string that contains a HTML fragment or document and
+// applies the policy allowlist, returning the sanitized HTML as a string.
+func (p *policy) Sanitize(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return s
+	}
+
+	var buf bytes.Buffer
+	if err := p.SanitizeReaderToWriter(strings.NewReader(s), &buf); err != nil {
+		return ""
+	}
+
+	return buf.String()
+}
+
+// SanitizeBytes takes a []byte that contains a HTML fragment or document
+// and applies the policy allowlist, returning the sanitized HTML as a
+// []byte.
+func (p *policy) SanitizeBytes(b []byte) []byte {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return b
+	}
+
+	var buf bytes.Buffer
+	if err := p.SanitizeReaderToWriter(bytes.NewReader(b), &buf); err != nil {
+		return nil
+	}
+
+	return buf.Bytes()
+}
+
+// SanitizeReaderToWriter reads a HTML fragment or document from r, applies
+// the policy allowlist, and streams the sanitized output to w as it is
+// produced. Unlike Sanitize/SanitizeBytes it never buffers the whole input
+// or output in memory, which matters for large documents such as mail
+// archives or wiki dumps. Sanitize and SanitizeBytes are thin wrappers
+// around this streaming core, so the token loop is written once.
+func (p *policy) SanitizeReaderToWriter(r io.Reader, w io.Writer) error {
+
+	sw, ok := w.(io.StringWriter)
+	if !ok {
+		sw = stringWriter{w}
+	}
+
+	z := html.NewTokenizer(r)
+
+	var skipElementContent string
+
+	// suppressedByHook tracks the names of start tags an OnElement hook
+	// dropped, in nesting order, so the matching end tag can be suppressed
+	// too instead of being emitted as an orphaned closing tag.
+	var suppressedByHook []string
+
+	for {
+		if z.Next() == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				return err
+			}
+			return nil
+		}
+
+		token := z.Token()
+
+		switch token.Type {
+		case html.DoctypeToken:
+			if p.allowDocType {
+				if _, err := sw.WriteString(token.String()); err != nil {
+					return err
+				}
+			}
+
+		case html.CommentToken:
+			// Comments are never retained.
+
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name := strings.ToLower(token.Data)
+
+			if skipElementContent != "" {
+				if token.Type == html.EndTagToken && name == skipElementContent {
+					skipElementContent = ""
+				}
+				continue
+			}
+
+			if token.Type == html.EndTagToken && len(suppressedByHook) > 0 && suppressedByHook[len(suppressedByHook)-1] == name {
+				suppressedByHook = suppressedByHook[:len(suppressedByHook)-1]
+				continue
+			}
+
+			elPolicies, allowed := p.elsAndAttrs[name]
+
+			if !allowed {
+				if token.Type == html.StartTagToken && skipContentElements[name] {
+					skipElementContent = name
+				}
+				continue
+			}
+
+			if token.Type == html.EndTagToken {
+				if _, err := sw.WriteString("</" + name + ">"); err != nil {
+					return err
+				}
+				continue
+			}
+
+			attrs := p.sanitizeAttrs(name, token.Attr, elPolicies)
+			attrs = p.runAttributeHooks(name, attrs)
+
+			attrs, keep := p.runElementHooks(name, attrs)
+			if !keep {
+				if token.Type == html.StartTagToken {
+					suppressedByHook = append(suppressedByHook, name)
+				}
+				continue
+			}
+
+			out := "<" + name
+			for _, a := range attrs {
+				out += " " + a.Key + `="` + html.EscapeString(a.Val) + `"`
+			}
+			if token.Type == html.SelfClosingTagToken {
+				out += "/"
+			}
+			out += ">"
+
+			if _, err := sw.WriteString(out); err != nil {
+				return err
+			}
+
+		case html.TextToken:
+			if skipElementContent != "" {
+				continue
+			}
+			if _, err := sw.WriteString(html.EscapeString(token.Data)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// SanitizeWriter returns an io.WriteCloser that sanitizes everything written
+// to it and forwards the result to w as it is produced; Close waits until
+// all of the output has been written to w and returns any error. It is the
+// streaming counterpart to SanitizeReaderToWriter for callers that produce
+// HTML incrementally (templates, chunked HTTP bodies) rather than holding
+// it as a single io.Reader.
+func (p *policy) SanitizeWriter(w io.Writer) io.WriteCloser {
+
+	pr, pw := io.Pipe()
+
+	swc := &sanitizingWriteCloser{pw: pw, done: make(chan error, 1)}
+
+	go func() {
+		swc.done <- p.SanitizeReaderToWriter(pr, w)
+	}()
+
+	return swc
+}
+
+type sanitizingWriteCloser struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func (swc *sanitizingWriteCloser) Write(b []byte) (int, error) {
+	return swc.pw.Write(b)
+}
+
+func (swc *sanitizingWriteCloser) Close() error {
+	if err := swc.pw.Close(); err != nil {
+		return err
+	}
+	return <-swc.done
+}
+
+// stringWriter adapts an io.Writer without a native WriteString to
+// io.StringWriter via a []byte conversion. SanitizeReaderToWriter only
+// falls back to it when the destination doesn't already implement
+// io.StringWriter; *bytes.Buffer and *bufio.Writer do, and take the fast
+// path that skips this conversion.
+type stringWriter struct {
+	w io.Writer
+}
+
+func (s stringWriter) WriteString(str string) (int, error) {
+	return s.w.Write([]byte(str))
+}
+
+// sanitizeAttrs filters attrs down to the ones el is allowed to carry
+// (per-element policies take precedence over global ones), enforces the
+// style="" and URL-attribute pipelines, and applies requireNoFollow.
+func (p *policy) sanitizeAttrs(el string, attrs []html.Attribute, elPolicies map[string]attrPolicy) []html.Attribute {
+
+	var out []html.Attribute
+
+	for _, a := range attrs {
+		name := strings.ToLower(a.Key)
+
+		ap, ok := elPolicies[name]
+		if !ok {
+			ap, ok = p.globalAttrs[name]
+		}
+		if !ok {
+			continue
+		}
+
+		if ap.regexp != nil && !ap.regexp.MatchString(a.Val) {
+			continue
+		}
+
+		if name == "style" {
+			val := p.sanitizeStyles(el, a.Val)
+			if val == "" {
+				continue
+			}
+			a.Val = val
+			out = append(out, a)
+			continue
+		}
+
+		if urlAttrs[name] && p.requireParseableURLs {
+			u, err := url.Parse(a.Val)
+			if err != nil {
+				continue
+			}
+
+			if !u.IsAbs() {
+				if !p.allowRelativeURLs {
+					continue
+				}
+			} else if !p.schemeAllowed(strings.ToLower(u.Scheme)) {
+				continue
+			}
+
+			sanitized, ok := p.sanitizeURL(u)
+			if !ok {
+				continue
+			}
+			a.Val = sanitized
+		}
+
+		out = append(out, a)
+	}
+
+	return p.applyLinkSafety(el, out)
+}
+
+// addRelToken merges token into an existing rel= attribute (preserving
+// order and de-duplicating), or appends a new rel= attribute if el doesn't
+// have one yet.
+func addRelToken(attrs []html.Attribute, token string) []html.Attribute {
+	for i, a := range attrs {
+		if strings.ToLower(a.Key) != "rel" {
+			continue
+		}
+		if hasToken(a.Val, token) {
+			return attrs
+		}
+		attrs[i].Val = strings.TrimSpace(a.Val + " " + token)
+		return attrs
+	}
+
+	return append(attrs, html.Attribute{Key: "rel", Val: token})
+}
+
+// hasToken reports whether token is present, case-insensitively, among the
+// space-separated tokens of value.
+func hasToken(value, token string) bool {
+	for _, t := range strings.Fields(value) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
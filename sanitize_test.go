@@ -0,0 +1,91 @@
+package bluemonday
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func basicPolicyForTest() *policy {
+	p := NewPolicy()
+	p.AllowElements("p", "a", "b")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowURLSchemes("http", "https")
+	p.RequireParseableURLs(true)
+	return p
+}
+
+func TestSanitizeDropsDisallowedElementsAndScriptContent(t *testing.T) {
+	p := basicPolicyForTest()
+
+	out := p.Sanitize(`<p>hi <script>alert(1)</script><b>bold</b></p>`)
+	if strings.Contains(out, "script") || strings.Contains(out, "alert") {
+		t.Errorf("Sanitize() = %q, script and its content should have been dropped", out)
+	}
+	if !strings.Contains(out, "<b>bold</b>") {
+		t.Errorf("Sanitize() = %q, allowed <b> should have survived", out)
+	}
+}
+
+func TestSanitizeDropsDisallowedURLScheme(t *testing.T) {
+	p := basicPolicyForTest()
+
+	out := p.Sanitize(`<a href="javascript:alert(1)">click</a>`)
+	if strings.Contains(out, "href") {
+		t.Errorf("Sanitize() = %q, javascript: href should have been dropped", out)
+	}
+}
+
+func TestSanitizeBytesMatchesSanitize(t *testing.T) {
+	p := basicPolicyForTest()
+
+	s := `<p>hi <a href="https://example.com">link</a></p>`
+	if got, want := string(p.SanitizeBytes([]byte(s))), p.Sanitize(s); got != want {
+		t.Errorf("SanitizeBytes() = %q, want %q", got, want)
+	}
+}
+
+func TestSanitizeReaderToWriterMatchesSanitize(t *testing.T) {
+	p := basicPolicyForTest()
+
+	s := `<p>hi <a href="https://example.com">link</a></p>`
+
+	var buf bytes.Buffer
+	if err := p.SanitizeReaderToWriter(strings.NewReader(s), &buf); err != nil {
+		t.Fatalf("SanitizeReaderToWriter() error = %v", err)
+	}
+
+	if got, want := buf.String(), p.Sanitize(s); got != want {
+		t.Errorf("SanitizeReaderToWriter() = %q, want %q", got, want)
+	}
+}
+
+func TestSanitizeWriterMatchesSanitize(t *testing.T) {
+	p := basicPolicyForTest()
+
+	s := `<p>hi <a href="https://example.com">link</a></p>`
+
+	var buf bytes.Buffer
+	sw := p.SanitizeWriter(&buf)
+	if _, err := sw.Write([]byte(s)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := sw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if got, want := buf.String(), p.Sanitize(s); got != want {
+		t.Errorf("SanitizeWriter() = %q, want %q", got, want)
+	}
+}
+
+func TestSanitizeRequireNoFollow(t *testing.T) {
+	p := basicPolicyForTest()
+	p.AllowAttrs("rel").OnElements("a")
+	p.RequireNoFollowOnLinks(true)
+
+	out := p.Sanitize(`<a href="https://example.com" rel="author">link</a>`)
+	if !strings.Contains(out, `rel="author nofollow"`) {
+		t.Errorf("Sanitize() = %q, expected nofollow merged into existing rel", out)
+	}
+}
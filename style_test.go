@@ -0,0 +1,148 @@
+package bluemonday
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func stylePolicyForTest() *policy {
+	p := NewPolicy()
+	p.AllowElements("p", "span")
+	p.AllowAttrs("style").OnElements("p", "span")
+	p.AllowURLSchemes("http", "https")
+	p.AllowStyles("color", "text-align").Globally()
+	p.AllowStyles("background-image").Globally()
+	return p
+}
+
+func TestSanitizeStylesAllowsSafeDeclarations(t *testing.T) {
+	p := stylePolicyForTest()
+
+	out := p.Sanitize(`<p style="color: red; text-align: center">hi</p>`)
+	if !strings.Contains(out, `color: red`) || !strings.Contains(out, `text-align: center`) {
+		t.Errorf("Sanitize() = %q, want both declarations kept", out)
+	}
+}
+
+func TestSanitizeStylesDropsDisallowedProperty(t *testing.T) {
+	p := stylePolicyForTest()
+
+	out := p.Sanitize(`<p style="color: red; position: fixed">hi</p>`)
+	if strings.Contains(out, "position") {
+		t.Errorf("Sanitize() = %q, should have dropped the unregistered position property", out)
+	}
+}
+
+func TestSanitizeStylesBlocksSingleURLScheme(t *testing.T) {
+	p := stylePolicyForTest()
+
+	out := p.Sanitize(`<p style="background-image: url(javascript:alert(1))">hi</p>`)
+	if strings.Contains(out, "background-image") {
+		t.Errorf("Sanitize() = %q, javascript: url() should have been dropped", out)
+	}
+}
+
+// TestSanitizeStylesBlocksURLSchemeInMultiValue is a regression test: a
+// comma-separated multi-url() value (as "background-image" commonly takes)
+// must have every url() checked, not just the value as a whole.
+func TestSanitizeStylesBlocksURLSchemeInMultiValue(t *testing.T) {
+	p := stylePolicyForTest()
+
+	out := p.Sanitize(`<p style="background-image: url(good.png), url(javascript:alert(1))">hi</p>`)
+	if strings.Contains(out, "background-image") {
+		t.Errorf("Sanitize() = %q, a value with any disallowed url() scheme must be dropped entirely", out)
+	}
+}
+
+func TestSanitizeStylesDefaultHandlers(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("p")
+	p.AllowAttrs("style").OnElements("p")
+	p.AllowStyles("transform", "opacity").Globally()
+
+	out := p.Sanitize(`<p style="transform: rotate(45deg); opacity: 0.5">hi</p>`)
+	if !strings.Contains(out, "rotate(45deg)") {
+		t.Errorf("Sanitize() = %q, transform: rotate(45deg) is valid CSS and should be kept", out)
+	}
+	if !strings.Contains(out, "opacity: 0.5") {
+		t.Errorf("Sanitize() = %q, opacity: 0.5 is valid CSS and should be kept", out)
+	}
+}
+
+// TestSanitizeStylesPreservesSemicolonInsideURL is a regression test: the
+// ";" inside a data: URI's "base64," marker must not be treated as a
+// declaration separator.
+func TestSanitizeStylesPreservesSemicolonInsideURL(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("p")
+	p.AllowAttrs("style").OnElements("p")
+	p.AllowStyles("background-image").Globally()
+	p.AllowURLSchemes("data")
+
+	out := p.Sanitize(`<p style="background-image: url(data:image/png;base64,AAAA==)">hi</p>`)
+	if !strings.Contains(out, "background-image: url(data:image/png;base64,AAAA==)") {
+		t.Errorf("Sanitize() = %q, a semicolon inside url(...) should not split the declaration", out)
+	}
+}
+
+// TestSanitizeStylesURLDomainParity is a regression test: a CSS url()
+// reference must be checked against the same AllowURLDomains allowlist
+// that guards href/src attributes, not just the flat scheme whitelist.
+func TestSanitizeStylesURLDomainParity(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("p")
+	p.AllowAttrs("style").OnElements("p")
+	p.AllowStyles("background-image").Globally()
+	p.AllowURLSchemes("https")
+	p.AllowURLDomains("trusted.org")
+
+	out := p.Sanitize(`<p style="background-image: url(https://evil.com/x.png)">hi</p>`)
+	if strings.Contains(out, "background-image") {
+		t.Errorf("Sanitize() = %q, AllowURLDomains should restrict CSS url() the same as it does href/src", out)
+	}
+
+	out = p.Sanitize(`<p style="background-image: url(https://trusted.org/x.png)">hi</p>`)
+	if !strings.Contains(out, "background-image") {
+		t.Errorf("Sanitize() = %q, a url() under the allowed domain should survive", out)
+	}
+}
+
+// TestSanitizeStylesURLDoesNotApplyRequireSanitizedURLRewrite documents a
+// known limitation: RequireSanitizedURL's veto is honored for CSS url()
+// values, but a rewrite is not, since sanitizeStyles never substitutes a
+// declaration's value — unlike href/src, which do pick up the rewrite.
+func TestSanitizeStylesURLDoesNotApplyRequireSanitizedURLRewrite(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("p")
+	p.AllowAttrs("style").OnElements("p")
+	p.AllowStyles("background-image").Globally()
+	p.AllowURLSchemes("http", "https")
+	p.RequireSanitizedURL(func(u *url.URL) (*url.URL, bool) {
+		u.Scheme = "https"
+		return u, true
+	})
+
+	out := p.Sanitize(`<p style="background-image: url(http://example.com/x.png)">hi</p>`)
+	if !strings.Contains(out, "url(http://example.com/x.png)") {
+		t.Errorf("Sanitize() = %q, CSS url() values are kept verbatim even when RequireSanitizedURL would rewrite them", out)
+	}
+}
+
+func TestAllowStylesMatchingRegexp(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("p")
+	p.AllowAttrs("style").OnElements("p")
+	p.AllowStyles("text-align").Matching(regexp.MustCompile(`(?i)^(left|right)$`)).OnElements("p")
+
+	out := p.Sanitize(`<p style="text-align: center">hi</p>`)
+	if strings.Contains(out, "text-align") {
+		t.Errorf("Sanitize() = %q, center should fail the left|right regexp", out)
+	}
+
+	out = p.Sanitize(`<p style="text-align: left">hi</p>`)
+	if !strings.Contains(out, "text-align: left") {
+		t.Errorf("Sanitize() = %q, left should pass the left|right regexp", out)
+	}
+}
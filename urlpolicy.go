@@ -0,0 +1,221 @@
+package bluemonday
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// urlPolicy bundles the ways a URL attribute can be constrained beyond the
+// plain p.urlSchemes whitelist: a scheme regexp, a set of allowed domains
+// (with single-level wildcard support), and a final hook that can rewrite
+// or veto the parsed URL outright.
+type urlPolicy struct {
+	schemeRegexp    *regexp.Regexp
+	domains         map[string]bool
+	wildcardDomains []string
+	sanitize        func(*url.URL) (*url.URL, bool)
+}
+
+// AllowURLSchemesMatching allows any URL scheme that matches regex, in
+// addition to (not instead of) the schemes registered via AllowURLSchemes.
+func (p *policy) AllowURLSchemesMatching(regex *regexp.Regexp) *policy {
+	p.urlPolicy().schemeRegexp = regex
+
+	return p
+}
+
+// AllowURLDomains restricts href/src/cite URLs to the given hosts, on top
+// of whatever schemes are already allowed. A pattern of the form
+// "*.example.com" matches example.com itself and any single level of
+// subdomain beneath it; any other pattern must match the host exactly.
+// Hosts are normalized with golang.org/x/net/idna before comparison, so an
+// IDN host and its "xn--" ASCII form are treated as the same domain.
+func (p *policy) AllowURLDomains(domains ...string) *policy {
+
+	up := p.urlPolicy()
+
+	for _, d := range domains {
+		d = strings.ToLower(d)
+
+		if strings.HasPrefix(d, "*.") {
+			up.wildcardDomains = append(up.wildcardDomains, normalizeHost(strings.TrimPrefix(d, "*.")))
+			continue
+		}
+
+		up.domains[normalizeHost(d)] = true
+	}
+
+	return p
+}
+
+// RequireSanitizedURL registers a final hook that every parsed href/src/cite
+// URL is passed through, after scheme and domain checks pass. Returning
+// (nil, false) drops the attribute; returning (u, true) keeps u, which may
+// be a rewritten *url.URL (e.g. to force https).
+func (p *policy) RequireSanitizedURL(fn func(*url.URL) (*url.URL, bool)) *policy {
+	p.urlPolicy().sanitize = fn
+
+	return p
+}
+
+func (p *policy) urlPolicy() *urlPolicy {
+	if p.urls == nil {
+		p.urls = &urlPolicy{domains: make(map[string]bool)}
+	}
+
+	return p.urls
+}
+
+// clone returns a deep copy of up, or nil if up is nil. The sanitize hook
+// is copied by reference, as funcs can't be deep-copied.
+func (up *urlPolicy) clone() *urlPolicy {
+	if up == nil {
+		return nil
+	}
+
+	cloned := &urlPolicy{
+		schemeRegexp:    up.schemeRegexp,
+		domains:         make(map[string]bool, len(up.domains)),
+		wildcardDomains: append([]string(nil), up.wildcardDomains...),
+		sanitize:        up.sanitize,
+	}
+
+	for d := range up.domains {
+		cloned.domains[d] = true
+	}
+
+	return cloned
+}
+
+// mergeInto unions other into up (creating up via clone if it was nil) and
+// returns the result. A scheme regexp or sanitize hook present on both
+// sides keeps up's; callers who need other's to win should call
+// RequireSanitizedURL/AllowURLSchemesMatching again after Merge.
+func (up *urlPolicy) mergeInto(other *urlPolicy) *urlPolicy {
+	if other == nil {
+		return up
+	}
+	if up == nil {
+		return other.clone()
+	}
+
+	if up.schemeRegexp == nil {
+		up.schemeRegexp = other.schemeRegexp
+	}
+
+	for d := range other.domains {
+		up.domains[d] = true
+	}
+
+	up.wildcardDomains = append(up.wildcardDomains, other.wildcardDomains...)
+
+	if up.sanitize == nil {
+		up.sanitize = other.sanitize
+	}
+
+	return up
+}
+
+// schemeAllowed reports whether scheme is permitted, either via the plain
+// p.urlSchemes whitelist or via AllowURLSchemesMatching.
+func (p *policy) schemeAllowed(scheme string) bool {
+	if p.urlSchemes[scheme] {
+		return true
+	}
+
+	return p.urls != nil && p.urls.schemeRegexp != nil && p.urls.schemeRegexp.MatchString(scheme)
+}
+
+// sanitizeURL runs u through the registered domain allowlist and
+// RequireSanitizedURL hook (scheme matching already having been applied by
+// the caller via schemeAllowed) and reports the URL string to keep.
+func (p *policy) sanitizeURL(u *url.URL) (string, bool) {
+
+	up := p.urls
+	if up == nil {
+		return u.String(), true
+	}
+
+	if len(up.domains) > 0 || len(up.wildcardDomains) > 0 {
+		if !up.hostAllowed(u.Hostname()) {
+			return "", false
+		}
+	}
+
+	if up.sanitize != nil {
+		sanitized, ok := up.sanitize(u)
+		if !ok {
+			return "", false
+		}
+		u = sanitized
+	}
+
+	return u.String(), true
+}
+
+func (up *urlPolicy) hostAllowed(host string) bool {
+
+	host = normalizeHost(host)
+
+	if up.domains[host] {
+		return true
+	}
+
+	for _, wildcard := range up.wildcardDomains {
+		if host == wildcard {
+			return true
+		}
+
+		// Only a single extra label is permitted between the subdomain
+		// and the registered wildcard root, e.g. "*.trusted.org" matches
+		// "cdn.trusted.org" but not "cdn.assets.trusted.org".
+		prefix := strings.TrimSuffix(host, "."+wildcard)
+		if prefix != host && !strings.Contains(prefix, ".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SanitizedURL parses rawURL and runs it through the same scheme, domain
+// and RequireSanitizedURL pipeline applied to href/src/cite attributes
+// during Sanitize, returning the URL string to keep and whether it passed.
+// It's useful for validating a URL that arrives outside of an HTML
+// attribute, e.g. one submitted through a separate form field, and is what
+// css.SanitizedStyleValues' doc comment refers to when it describes
+// mirroring "the accept/reject contract of bluemonday.SanitizedURL".
+func (p *policy) SanitizedURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+
+	if !u.IsAbs() {
+		if !p.allowRelativeURLs {
+			return "", false
+		}
+		return p.sanitizeURL(u)
+	}
+
+	if !p.schemeAllowed(strings.ToLower(u.Scheme)) {
+		return "", false
+	}
+
+	return p.sanitizeURL(u)
+}
+
+// normalizeHost lowercases and IDN-normalizes host so that Unicode and
+// "xn--" forms of the same domain compare equal; a host that fails to
+// normalize (rare, usually malformed input) is compared as-is.
+func normalizeHost(host string) string {
+	norm, err := idna.ToASCII(strings.ToLower(host))
+	if err != nil {
+		return strings.ToLower(host)
+	}
+
+	return norm
+}
@@ -0,0 +1,116 @@
+package bluemonday
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func domainPolicyForTest() *policy {
+	p := NewPolicy()
+	p.AllowElements("a")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowURLSchemes("http", "https")
+	p.RequireParseableURLs(true)
+	p.AllowURLDomains("trusted.org", "*.cdn.example.com")
+	return p
+}
+
+func TestAllowURLDomainsExactMatch(t *testing.T) {
+	p := domainPolicyForTest()
+
+	out := p.Sanitize(`<a href="https://trusted.org/page">x</a>`)
+	if !strings.Contains(out, `href="https://trusted.org/page"`) {
+		t.Errorf("Sanitize() = %q, exact domain match should be kept", out)
+	}
+
+	out = p.Sanitize(`<a href="https://evil.com">x</a>`)
+	if strings.Contains(out, "href") {
+		t.Errorf("Sanitize() = %q, non-allowed domain should be dropped", out)
+	}
+}
+
+func TestAllowURLDomainsSingleLevelWildcard(t *testing.T) {
+	p := domainPolicyForTest()
+
+	out := p.Sanitize(`<a href="https://assets.cdn.example.com/x.png">x</a>`)
+	if !strings.Contains(out, "assets.cdn.example.com") {
+		t.Errorf("Sanitize() = %q, one level of subdomain under the wildcard should match", out)
+	}
+
+	out = p.Sanitize(`<a href="https://a.b.cdn.example.com/x.png">x</a>`)
+	if strings.Contains(out, "href") {
+		t.Errorf("Sanitize() = %q, two levels of subdomain should not match a single-level wildcard", out)
+	}
+}
+
+func TestAllowURLDomainsIDNNormalization(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("a")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowURLSchemes("https")
+	p.RequireParseableURLs(true)
+	p.AllowURLDomains("xn--mnchen-3ya.de")
+
+	out := p.Sanitize(`<a href="https://m` + "ü" + `nchen.de/">x</a>`)
+	if !strings.Contains(out, "href") {
+		t.Errorf("Sanitize() = %q, unicode host should have matched its xn-- form via IDN normalization", out)
+	}
+}
+
+func TestAllowURLSchemesMatching(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("a")
+	p.AllowAttrs("href").OnElements("a")
+	p.RequireParseableURLs(true)
+	p.AllowURLSchemesMatching(regexp.MustCompile(`^(?:https?|ftp)$`))
+
+	out := p.Sanitize(`<a href="ftp://example.com/file">x</a>`)
+	if !strings.Contains(out, "ftp://example.com") {
+		t.Errorf("Sanitize() = %q, ftp should have been allowed via the scheme regexp", out)
+	}
+
+	out = p.Sanitize(`<a href="javascript:alert(1)">x</a>`)
+	if strings.Contains(out, "href") {
+		t.Errorf("Sanitize() = %q, javascript: should not match the scheme regexp", out)
+	}
+}
+
+func TestRequireSanitizedURLCanVetoOrRewrite(t *testing.T) {
+	p := NewPolicy()
+	p.AllowElements("a")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowURLSchemes("http", "https")
+	p.RequireParseableURLs(true)
+	p.RequireSanitizedURL(func(u *url.URL) (*url.URL, bool) {
+		if u.Hostname() == "blocked.example.com" {
+			return nil, false
+		}
+		u.Scheme = "https"
+		return u, true
+	})
+
+	out := p.Sanitize(`<a href="http://ok.example.com">x</a>`)
+	if !strings.Contains(out, `href="https://ok.example.com"`) {
+		t.Errorf("Sanitize() = %q, RequireSanitizedURL should have rewritten the scheme", out)
+	}
+
+	out = p.Sanitize(`<a href="http://blocked.example.com">x</a>`)
+	if strings.Contains(out, "href") {
+		t.Errorf("Sanitize() = %q, RequireSanitizedURL should have vetoed this host", out)
+	}
+}
+
+func TestPolicySanitizedURL(t *testing.T) {
+	p := domainPolicyForTest()
+
+	if _, ok := p.SanitizedURL("https://evil.com"); ok {
+		t.Error("SanitizedURL() ok = true, want false for a disallowed domain")
+	}
+
+	got, ok := p.SanitizedURL("https://trusted.org/page")
+	if !ok || got != "https://trusted.org/page" {
+		t.Errorf("SanitizedURL() = %q, %v, want https://trusted.org/page, true", got, ok)
+	}
+}